@@ -0,0 +1,40 @@
+// Package events defines the event types state mutations publish onto the
+// node's event stream, so RPC clients can subscribe to them instead of
+// polling.
+package events
+
+import (
+	"fmt"
+
+	. "github.com/tendermint/tendermint/common"
+)
+
+// EventData is the payload carried alongside an event string on the event
+// stream. Concrete event types implement it so Fireable.FireEvent has a
+// single argument type regardless of what kind of event is being fired.
+type EventData interface {
+	AssertIsEventData()
+}
+
+// Fireable is implemented by whatever publishes events onto the stream --
+// typically the same appState a snative or PermissionsTx mutates, so a
+// state change is visible to subscribers in the same call that makes it.
+type Fireable interface {
+	FireEvent(event string, data EventData)
+}
+
+// EventDataPermissions is fired whenever a base permission, global
+// permission, or role is added, removed, or changed for an account.
+type EventDataPermissions struct {
+	Address Word256
+}
+
+func (EventDataPermissions) AssertIsEventData() {}
+
+// EventStringPermissions returns the event string subscribers watch for
+// permission/role changes to the account at addr, mirroring the
+// Acc/<address>/<suffix> convention the rest of the account event stream
+// already uses.
+func EventStringPermissions(addr Word256) string {
+	return fmt.Sprintf("Acc/%X/Permissions", addr.Postfix(20))
+}
@@ -0,0 +1,23 @@
+package events
+
+import (
+	"testing"
+
+	. "github.com/tendermint/tendermint/common"
+)
+
+func TestEventStringPermissionsIsAddressScoped(t *testing.T) {
+	addr := LeftPadWord256([]byte("target"))
+	other := LeftPadWord256([]byte("other"))
+
+	if EventStringPermissions(addr) == EventStringPermissions(other) {
+		t.Fatalf("expected distinct addresses to produce distinct event strings")
+	}
+	if EventStringPermissions(addr) != EventStringPermissions(addr) {
+		t.Fatalf("expected EventStringPermissions to be deterministic for the same address")
+	}
+}
+
+func TestEventDataPermissionsSatisfiesEventData(t *testing.T) {
+	var _ EventData = EventDataPermissions{}
+}
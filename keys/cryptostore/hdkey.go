@@ -0,0 +1,268 @@
+package cryptostore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	crypto "github.com/tendermint/go-crypto"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// hardenedOffset is added to a BIP44 path segment's index to mark it as
+// hardened, exactly like the ' suffix does in the human-readable path
+// (e.g. "44'" -> 44+hardenedOffset).
+const hardenedOffset = uint32(0x80000000)
+
+// hdKeyTypeByte tags an hdKey's serialized Bytes() so Export/Import can
+// tell it apart from a plain crypto.PrivKeySecp256k1 -- crypto.PrivKeyFromBytes
+// only knows the leaf key types go-crypto registers, not this package's
+// chain-code-carrying wrapper.
+const hdKeyTypeByte = 0x05
+
+// secp256k1N is the order of the secp256k1 curve's base point: every BIP32
+// child private key is its parent plus a tweak, reduced mod this.
+var secp256k1N, _ = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+
+// hdKey is a secp256k1 private key paired with the BIP32 chain code
+// produced alongside it, so a master key stored under a Manager name can
+// go on to derive a whole BIP44 subtree. It satisfies crypto.PrivKey, so
+// the rest of Manager (Sign, es.Get/Put, ...) handles it like any other
+// stored key without special-casing.
+type hdKey struct {
+	priv      crypto.PrivKeySecp256k1
+	chainCode [32]byte
+}
+
+func (k hdKey) Bytes() []byte {
+	bz := make([]byte, 0, 1+32+32)
+	bz = append(bz, hdKeyTypeByte)
+	bz = append(bz, k.priv[:]...)
+	bz = append(bz, k.chainCode[:]...)
+	return bz
+}
+
+func (k hdKey) Sign(msg []byte) crypto.Signature {
+	return k.priv.Sign(msg)
+}
+
+func (k hdKey) PubKey() crypto.PubKey {
+	return k.priv.PubKey()
+}
+
+func (k hdKey) Equals(other crypto.PrivKey) bool {
+	o, ok := other.(hdKey)
+	return ok && k.priv.Equals(o.priv) && k.chainCode == o.chainCode
+}
+
+// hdKeyFromBytes reverses hdKey.Bytes, for Import to use once it has
+// decrypted an exported hdKey blob.
+func hdKeyFromBytes(bz []byte) (hdKey, bool) {
+	if len(bz) != 1+32+32 || bz[0] != hdKeyTypeByte {
+		return hdKey{}, false
+	}
+	var k hdKey
+	copy(k.priv[:], bz[1:33])
+	copy(k.chainCode[:], bz[33:65])
+	return k, true
+}
+
+// randomEntropy returns the raw entropy a numWords-word BIP39 mnemonic
+// encodes: 16 bytes for 12 words, 32 bytes for 24.
+func randomEntropy(numWords int) ([]byte, error) {
+	var n int
+	switch numWords {
+	case 12:
+		n = 16
+	case 24:
+		n = 32
+	default:
+		return nil, fmt.Errorf("unsupported mnemonic length %d (must be 12 or 24 words)", numWords)
+	}
+	entropy := make([]byte, n)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// seedFromMnemonic stretches a mnemonic phrase (plus an optional BIP39
+// passphrase) into the 64-byte seed BIP32 master key derivation consumes,
+// via the standard PBKDF2-HMAC-SHA512 construction.
+func seedFromMnemonic(mnemonic, passphrase string) []byte {
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+}
+
+// masterKeyFromSeed derives the BIP32 master key and chain code from seed
+// via HMAC-SHA512 keyed by the curve-specific constant "Bitcoin seed".
+func masterKeyFromSeed(seed []byte) hdKey {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	I := mac.Sum(nil)
+
+	var k hdKey
+	copy(k.priv[:], I[:32])
+	copy(k.chainCode[:], I[32:])
+	return k
+}
+
+// deriveChild derives the index'th BIP32 child of k. index >= hardenedOffset
+// derives a hardened child, which tweaks from the parent's private key
+// rather than its public key.
+func deriveChild(k hdKey, index uint32) (hdKey, error) {
+	var data []byte
+	if index >= hardenedOffset {
+		data = append([]byte{0x00}, k.priv[:]...)
+	} else {
+		pub, ok := k.priv.PubKey().(crypto.PubKeySecp256k1)
+		if !ok {
+			return hdKey{}, fmt.Errorf("unexpected public key type deriving non-hardened child")
+		}
+		data = append([]byte{}, pub[:]...)
+	}
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], index)
+	data = append(data, idx[:]...)
+
+	mac := hmac.New(sha512.New, k.chainCode[:])
+	mac.Write(data)
+	I := mac.Sum(nil)
+
+	childPriv, err := addPrivKeys(k.priv[:], I[:32])
+	if err != nil {
+		return hdKey{}, err
+	}
+
+	var child hdKey
+	copy(child.priv[:], childPriv)
+	copy(child.chainCode[:], I[32:])
+	return child, nil
+}
+
+// addPrivKeys adds two 32-byte scalars mod the secp256k1 curve order, the
+// tweak BIP32 child derivation applies to a parent private key.
+func addPrivKeys(a, b []byte) ([]byte, error) {
+	sum := new(big.Int).Add(new(big.Int).SetBytes(a), new(big.Int).SetBytes(b))
+	sum.Mod(sum, secp256k1N)
+	if sum.Sign() == 0 {
+		return nil, fmt.Errorf("derived a zero private key, retry with a different index")
+	}
+	out := make([]byte, 32)
+	sum.FillBytes(out)
+	return out, nil
+}
+
+// parseHDPath parses a BIP44-style path such as "m/44'/118'/0'/0/0" into
+// its per-level indices, with hardened levels (trailing ') offset into the
+// upper half of uint32 as deriveChild expects.
+func parseHDPath(path string) ([]uint32, error) {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "m/")
+	if path == "" {
+		return nil, fmt.Errorf("empty HD path")
+	}
+	segments := strings.Split(path, "/")
+	indices := make([]uint32, len(segments))
+	for i, seg := range segments {
+		hardened := strings.HasSuffix(seg, "'")
+		n, err := strconv.ParseUint(strings.TrimSuffix(seg, "'"), 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid HD path segment %q: %v", seg, err)
+		}
+		idx := uint32(n)
+		if hardened {
+			idx += hardenedOffset
+		}
+		indices[i] = idx
+	}
+	return indices, nil
+}
+
+// hdExportPrefix marks an Export blob produced from an hdKey, so Import
+// knows to route it through decryptHDKey instead of handing it to
+// s.es.coder, whose Decrypt ultimately calls crypto.PrivKeyFromBytes and
+// doesn't know about the chain code hdKey tacks on.
+var hdExportPrefix = []byte("hdk1:")
+
+// hdKeyCipherSaltSize is the length of the random salt encryptHDKey
+// prepends to its output, so decryptHDKey can re-derive the same AES key
+// without the salt ever needing to be transmitted out of band.
+const hdKeyCipherSaltSize = 16
+
+// hdKeyCipherIterations is the PBKDF2 round count used to stretch an
+// Export/Import transfer passphrase into an AES-256 key, the same
+// key-stretching treatment seedFromMnemonic already gives a BIP39
+// passphrase. A bare SHA-256 hash of the passphrase has zero rounds and
+// no salt, so it's brute-forceable offline in seconds against anything
+// but a high-entropy passphrase -- exactly what this key is meant to
+// protect, since it carries a whole HD tree of addresses.
+const hdKeyCipherIterations = 4096
+
+// encryptHDKey seals hd's serialized bytes with a key derived from pass
+// and a fresh random salt, for Export to carry an hdKey, which s.es.coder
+// cannot round-trip. The output is salt, then GCM nonce, then ciphertext,
+// so decryptHDKey can recover the salt and nonce from the blob itself.
+func encryptHDKey(hd hdKey, pass string) ([]byte, error) {
+	salt := make([]byte, hdKeyCipherSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := hdKeyCipher(pass, salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, hd.Bytes(), nil)
+	return append(salt, sealed...), nil
+}
+
+// decryptHDKey reverses encryptHDKey.
+func decryptHDKey(data []byte, pass string) (hdKey, error) {
+	if len(data) < hdKeyCipherSaltSize {
+		return hdKey{}, fmt.Errorf("hd key ciphertext too short")
+	}
+	salt, rest := data[:hdKeyCipherSaltSize], data[hdKeyCipherSaltSize:]
+
+	gcm, err := hdKeyCipher(pass, salt)
+	if err != nil {
+		return hdKey{}, err
+	}
+	if len(rest) < gcm.NonceSize() {
+		return hdKey{}, fmt.Errorf("hd key ciphertext too short")
+	}
+	nonce, ciphertext := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return hdKey{}, err
+	}
+	key, ok := hdKeyFromBytes(plain)
+	if !ok {
+		return hdKey{}, fmt.Errorf("not a valid hd key export")
+	}
+	return key, nil
+}
+
+func hdKeyCipher(pass string, salt []byte) (cipher.AEAD, error) {
+	passKey := pbkdf2.Key([]byte(pass), salt, hdKeyCipherIterations, 32, sha256.New)
+	block, err := aes.NewCipher(passKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// isHDExport reports whether data was produced by encryptHDKey.
+func isHDExport(data []byte) bool {
+	return bytes.HasPrefix(data, hdExportPrefix)
+}
@@ -0,0 +1,124 @@
+package cryptostore
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestParseHDPath(t *testing.T) {
+	indices, err := parseHDPath("m/44'/118'/0'/0/5")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []uint32{
+		44 + hardenedOffset,
+		118 + hardenedOffset,
+		0 + hardenedOffset,
+		0,
+		5,
+	}
+	if len(indices) != len(want) {
+		t.Fatalf("got %d indices, want %d", len(indices), len(want))
+	}
+	for i, idx := range indices {
+		if idx != want[i] {
+			t.Errorf("index %d = %d, want %d", i, idx, want[i])
+		}
+	}
+}
+
+func TestParseHDPathRejectsGarbage(t *testing.T) {
+	for _, path := range []string{"", "m/", "m/44'/abc"} {
+		if _, err := parseHDPath(path); err == nil {
+			t.Errorf("parseHDPath(%q) should have failed", path)
+		}
+	}
+}
+
+func TestMasterKeyFromSeedIsDeterministic(t *testing.T) {
+	seed := []byte("a fixed 60 byte seed used only to check determinism here, ok")
+	a := masterKeyFromSeed(seed)
+	b := masterKeyFromSeed(seed)
+	if a.priv != b.priv || a.chainCode != b.chainCode {
+		t.Fatalf("expected the same seed to always derive the same master key")
+	}
+}
+
+func TestDeriveChildHardenedVsNormalDiffer(t *testing.T) {
+	master := masterKeyFromSeed([]byte("another fixed seed for this test, long enough"))
+
+	hardened, err := deriveChild(master, 0+hardenedOffset)
+	if err != nil {
+		t.Fatalf("unexpected error deriving hardened child: %v", err)
+	}
+	normal, err := deriveChild(master, 0)
+	if err != nil {
+		t.Fatalf("unexpected error deriving normal child: %v", err)
+	}
+	if hardened.priv == normal.priv {
+		t.Fatalf("hardened and normal child 0 should not derive to the same key")
+	}
+}
+
+func TestHDKeyBytesRoundTrip(t *testing.T) {
+	master := masterKeyFromSeed([]byte("seed used to check hdKey (de)serialization roundtrip"))
+	got, ok := hdKeyFromBytes(master.Bytes())
+	if !ok {
+		t.Fatalf("expected hdKeyFromBytes to recognize master.Bytes()")
+	}
+	if got.priv != master.priv || got.chainCode != master.chainCode {
+		t.Fatalf("hdKeyFromBytes did not round-trip master")
+	}
+}
+
+func TestEncryptDecryptHDKeyRoundTrip(t *testing.T) {
+	master := masterKeyFromSeed([]byte("seed used to check hd key export encryption roundtrip"))
+
+	enc, err := encryptHDKey(master, "transfer-pass")
+	if err != nil {
+		t.Fatalf("unexpected error encrypting: %v", err)
+	}
+	dec, err := decryptHDKey(enc, "transfer-pass")
+	if err != nil {
+		t.Fatalf("unexpected error decrypting: %v", err)
+	}
+	if dec.priv != master.priv || dec.chainCode != master.chainCode {
+		t.Fatalf("decrypted hd key did not match the original")
+	}
+
+	if _, err := decryptHDKey(enc, "wrong-pass"); err == nil {
+		t.Fatalf("expected decryption with the wrong passphrase to fail")
+	}
+}
+
+// TestEncryptHDKeyUsesARandomSalt guards against regressing to a bare,
+// unsalted passphrase hash: encrypting the same key under the same
+// passphrase twice must not derive the same AES key (and so must not
+// produce the same ciphertext prefix), or an attacker could brute-force
+// the passphrase entirely offline with no per-export cost.
+func TestEncryptHDKeyUsesARandomSalt(t *testing.T) {
+	master := masterKeyFromSeed([]byte("seed used to check hd key export salting"))
+
+	first, err := encryptHDKey(master, "transfer-pass")
+	if err != nil {
+		t.Fatalf("unexpected error encrypting: %v", err)
+	}
+	second, err := encryptHDKey(master, "transfer-pass")
+	if err != nil {
+		t.Fatalf("unexpected error encrypting: %v", err)
+	}
+	if len(first) < hdKeyCipherSaltSize || len(second) < hdKeyCipherSaltSize {
+		t.Fatalf("expected both exports to carry at least a salt's worth of bytes")
+	}
+	if bytes.Equal(first[:hdKeyCipherSaltSize], second[:hdKeyCipherSaltSize]) {
+		t.Fatalf("expected two exports of the same key/passphrase to use distinct random salts")
+	}
+
+	dec, err := decryptHDKey(second, "transfer-pass")
+	if err != nil {
+		t.Fatalf("unexpected error decrypting: %v", err)
+	}
+	if dec.priv != master.priv || dec.chainCode != master.chainCode {
+		t.Fatalf("decrypted hd key did not match the original")
+	}
+}
@@ -1,6 +1,7 @@
 package cryptostore
 
 import (
+	"fmt"
 	"strings"
 
 	crypto "github.com/tendermint/go-crypto"
@@ -34,12 +35,34 @@ func (s Manager) assertKeyManager() keys.Manager {
 	return s
 }
 
+// algoSecp256k1HD12 and algoSecp256k1HD24 are Create's algo values for an
+// HD wallet: rather than generating a single leaf key, they generate a
+// 12- or 24-word BIP39 mnemonic and derive the BIP32 master key it seeds.
+// Derive then walks a BIP44 path from that master to mint child keys.
+const (
+	algoSecp256k1HD12 = "secp256k1-hd12"
+	algoSecp256k1HD24 = "secp256k1-hd24"
+)
+
+// mnemonicPrefix tags a seedphrase returned by createMnemonic as a BIP39
+// mnemonic, the same way hdExportPrefix tags an Export blob produced from
+// an hdKey. Recover needs this to tell a mnemonic apart from the raw-key
+// phrase Create's non-HD path has always produced: both can land on 12 or
+// 24 words under the same wordlist, so word count alone can't be trusted
+// to disambiguate them -- a pre-existing 24-word raw key would silently
+// get derived as a mnemonic and yield the wrong private key.
+const mnemonicPrefix = "bip39:"
+
 // Create adds a new key to the storage engine, returning error if
 // another key already stored under this name
 //
-// algo must be a supported go-crypto algorithm:
-//
+// algo must be a supported go-crypto algorithm, or one of
+// algoSecp256k1HD12 / algoSecp256k1HD24 for an HD wallet master key.
 func (s Manager) Create(name, passphrase, algo string) (keys.Info, string, error) {
+	if numWords, ok := hdMnemonicWords(algo); ok {
+		return s.createMnemonic(name, passphrase, numWords)
+	}
+
 	gen, err := getGenerator(algo)
 	if err != nil {
 		return keys.Info{}, "", err
@@ -54,9 +77,67 @@ func (s Manager) Create(name, passphrase, algo string) (keys.Info, string, error
 	return info(name, key), phrase, err
 }
 
-func (s Manager) Recover(name, passphrase, seedphrase string) (keys.Info, error) {
-	words := strings.Split(strings.TrimSpace(seedphrase), " ")
-	data, err := s.codec.WordsToBytes(words)
+func hdMnemonicWords(algo string) (int, bool) {
+	switch algo {
+	case algoSecp256k1HD12:
+		return 12, true
+	case algoSecp256k1HD24:
+		return 24, true
+	default:
+		return 0, false
+	}
+}
+
+// createMnemonic generates a fresh numWords-word BIP39 mnemonic from the
+// codec's wordlist, derives the BIP32 master key it seeds, and stores that
+// master under name so Derive can later walk BIP44 paths from it.
+func (s Manager) createMnemonic(name, passphrase string, numWords int) (keys.Info, string, error) {
+	entropy, err := randomEntropy(numWords)
+	if err != nil {
+		return keys.Info{}, "", err
+	}
+	words, err := s.codec.BytesToWords(entropy)
+	if err != nil {
+		return keys.Info{}, "", err
+	}
+	phrase := strings.Join(words, " ")
+
+	key := masterKeyFromSeed(seedFromMnemonic(phrase, ""))
+	phrase = mnemonicPrefix + phrase
+	if err := s.es.Put(name, passphrase, key); err != nil {
+		return keys.Info{}, "", err
+	}
+	return info(name, key), phrase, nil
+}
+
+// Recover re-derives a key from seedphrase and stores it under name.
+// seedphrase is either the raw-key-bytes phrase Create's non-HD path has
+// always produced, or a mnemonicPrefix-tagged BIP39 mnemonic from createMnemonic
+// -- in the latter case an optional bip39Passphrase further salts the
+// derived seed, exactly like a hardware wallet's passphrase-protected
+// wallets. The tag, not word count, decides which path to take: a 12- or
+// 24-word raw-key phrase is indistinguishable from a mnemonic by length
+// alone under the same wordlist.
+func (s Manager) Recover(name, passphrase, seedphrase string, bip39Passphrase ...string) (keys.Info, error) {
+	trimmed := strings.TrimSpace(seedphrase)
+
+	if mnemonic, ok := stripMnemonicPrefix(trimmed); ok {
+		words := strings.Fields(mnemonic)
+		if _, err := s.codec.WordsToBytes(words); err != nil {
+			return keys.Info{}, err
+		}
+		var hdPass string
+		if len(bip39Passphrase) > 0 {
+			hdPass = bip39Passphrase[0]
+		}
+		key := masterKeyFromSeed(seedFromMnemonic(strings.Join(words, " "), hdPass))
+		if err := s.es.Put(name, passphrase, key); err != nil {
+			return keys.Info{}, err
+		}
+		return info(name, key), nil
+	}
+
+	data, err := s.codec.WordsToBytes(strings.Fields(trimmed))
 	if err != nil {
 		return keys.Info{}, err
 	}
@@ -71,6 +152,50 @@ func (s Manager) Recover(name, passphrase, seedphrase string) (keys.Info, error)
 	return info(name, key), err
 }
 
+// stripMnemonicPrefix reports whether seedphrase carries mnemonicPrefix
+// and, if so, returns the mnemonic text with the tag removed.
+func stripMnemonicPrefix(seedphrase string) (string, bool) {
+	if !strings.HasPrefix(seedphrase, mnemonicPrefix) {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimPrefix(seedphrase, mnemonicPrefix)), true
+}
+
+// Derive walks the BIP44-style path (e.g. "m/44'/118'/0'/0/0") from the
+// master key stored under name, persists the derived child under a new
+// name scoped to name and path, and returns its info. The Signer.Sign path
+// needs no special handling to sign with the result: hdKey satisfies
+// crypto.PrivKey like any other stored key.
+func (s Manager) Derive(name, passphrase, path string) (keys.Info, error) {
+	stored, _, err := s.es.Get(name, passphrase)
+	if err != nil {
+		return keys.Info{}, err
+	}
+	master, ok := stored.(hdKey)
+	if !ok {
+		return keys.Info{}, fmt.Errorf("%q has no chain code to derive from; create it with algo %q or %q", name, algoSecp256k1HD12, algoSecp256k1HD24)
+	}
+
+	indices, err := parseHDPath(path)
+	if err != nil {
+		return keys.Info{}, err
+	}
+
+	child := master
+	for _, index := range indices {
+		child, err = deriveChild(child, index)
+		if err != nil {
+			return keys.Info{}, err
+		}
+	}
+
+	childName := name + "/" + path
+	if err := s.es.Put(childName, passphrase, child); err != nil {
+		return keys.Info{}, err
+	}
+	return info(childName, child), nil
+}
+
 // List loads the keys from the storage and enforces alphabetical order
 func (s Manager) List() (keys.Infos, error) {
 	res, err := s.es.List()
@@ -110,6 +235,18 @@ func (s Manager) Export(name, oldpass, transferpass string) ([]byte, error) {
 		return nil, err
 	}
 
+	// hdKey carries a chain code alongside the private key that
+	// s.es.coder's Decrypt can't reconstruct -- it hands its plaintext to
+	// crypto.PrivKeyFromBytes, which only knows go-crypto's own leaf key
+	// types. Round-trip it ourselves instead, tagged so Import can tell.
+	if hd, ok := key.(hdKey); ok {
+		enc, err := encryptHDKey(hd, transferpass)
+		if err != nil {
+			return nil, err
+		}
+		return append(append([]byte{}, hdExportPrefix...), enc...), nil
+	}
+
 	res, err := s.es.coder.Encrypt(key, transferpass)
 	return res, err
 }
@@ -118,6 +255,14 @@ func (s Manager) Export(name, oldpass, transferpass string) ([]byte, error) {
 // If they are valid, it stores the password under the given name with the
 // new passphrase.
 func (s Manager) Import(name, newpass, transferpass string, data []byte) error {
+	if isHDExport(data) {
+		hd, err := decryptHDKey(data[len(hdExportPrefix):], transferpass)
+		if err != nil {
+			return err
+		}
+		return s.es.Put(name, newpass, hd)
+	}
+
 	key, err := s.es.coder.Decrypt(data, transferpass)
 	if err != nil {
 		return err
@@ -0,0 +1,25 @@
+package cryptostore
+
+import "testing"
+
+// TestStripMnemonicPrefixDisambiguatesRawKeyWords is the regression case
+// for Recover mis-routing a pre-existing 24-word raw-key seedphrase into
+// the BIP39-mnemonic branch: word count alone can't tell the two apart,
+// so only a mnemonicPrefix-tagged phrase should be treated as a mnemonic.
+func TestStripMnemonicPrefixDisambiguatesRawKeyWords(t *testing.T) {
+	rawKeyPhrase := "one two three four five six seven eight nine ten " +
+		"eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen " +
+		"nineteen twenty twentyone twentytwo twentythree twentyfour"
+
+	if _, ok := stripMnemonicPrefix(rawKeyPhrase); ok {
+		t.Fatalf("untagged 24-word raw-key phrase must not be treated as a mnemonic")
+	}
+
+	mnemonic, ok := stripMnemonicPrefix(mnemonicPrefix + rawKeyPhrase)
+	if !ok {
+		t.Fatalf("expected a %q-tagged phrase to be recognized as a mnemonic", mnemonicPrefix)
+	}
+	if mnemonic != rawKeyPhrase {
+		t.Fatalf("expected tag to be stripped cleanly, got %q", mnemonic)
+	}
+}
@@ -0,0 +1,41 @@
+package types
+
+import "fmt"
+
+// errInvalidPermission is returned by ErrInvalidPermission and by anything
+// in this package that rejects an out-of-range PermFlag.
+type errInvalidPermission struct {
+	Flag PermFlag
+}
+
+func (e errInvalidPermission) Error() string {
+	return fmt.Sprintf("invalid permission flag: %b", e.Flag)
+}
+
+// ErrInvalidPermission builds the error returned when a PermFlag is outside
+// both the base and snative ranges (see vm.ValidPermN).
+func ErrInvalidPermission(flag PermFlag) error {
+	return errInvalidPermission{Flag: flag}
+}
+
+// permNameToFlag maps the capitalised snative name (e.g. "SetBase") to its
+// PermFlag, the reverse of how snativeInfo.Name is keyed.
+var permNameToFlag = map[string]PermFlag{
+	"HasBase":   HasBase,
+	"SetBase":   SetBase,
+	"UnsetBase": UnsetBase,
+	"SetGlobal": SetGlobal,
+	"HasRole":   HasRole,
+	"AddRole":   AddRole,
+	"RmRole":    RmRole,
+}
+
+// SNativeStringToPermFlag looks up the snative permission flag for a
+// capitalised snative name, e.g. "SetBase" -> SetBase.
+func SNativeStringToPermFlag(name string) (PermFlag, error) {
+	flag, ok := permNameToFlag[name]
+	if !ok {
+		return 0, fmt.Errorf("unknown snative permission name %q", name)
+	}
+	return flag, nil
+}
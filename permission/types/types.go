@@ -0,0 +1,175 @@
+package types
+
+import (
+	"fmt"
+
+	. "github.com/tendermint/tendermint/common"
+)
+
+const (
+	// MaxRoleNameLength is the longest a role name may be: the width of the
+	// bytes32 the Solidity-style snatives pack it into.
+	MaxRoleNameLength = 32
+	// MaxRolesPerAccount caps how many roles a single account can hold, so
+	// AddRole can't be used to grow an account's permission state without
+	// bound.
+	MaxRolesPerAccount = 256
+)
+
+// PermFlag is a bit flag over the base chain permissions (the low 32 bits)
+// and the snative permissions (the high 32 bits); see the two const blocks
+// below.
+type PermFlag uint64
+
+// Base chain permissions occupy bits 0-31.
+const (
+	Root PermFlag = 1 << iota
+	Send
+	Call
+	CreateContract
+	CreateAccount
+	Bond
+	Name
+	// Role must be held (directly, or granted globally on
+	// GlobalPermissionsAddress256) before AddRole/RmRole will succeed.
+	Role
+
+	TopBasePermFlag = Role
+)
+
+// FirstSNativePermFlag marks the start of the snative permission range.
+const FirstSNativePermFlag PermFlag = 1 << 32
+
+// Snative permissions occupy a dedicated range starting at bit 32, so they
+// can never collide with a base chain permission bit.
+const (
+	HasBase PermFlag = FirstSNativePermFlag << iota
+	SetBase
+	UnsetBase
+	SetGlobal
+	HasRole
+	AddRole
+	RmRole
+
+	TopSNativePermFlag = RmRole
+)
+
+// GlobalPermissionsAddress256 is the well-known account whose base
+// permissions are consulted when an account itself has no opinion on a
+// given permission flag (see vm.HasPermission) -- the chain-wide default.
+var GlobalPermissionsAddress256 = LeftPadWord256([]byte("GlobalPermissionsAddress"))
+
+// BasePermissions is a permission bitmap plus a parallel "this bit has been
+// explicitly set" mask, so "not set" (defer to the global account) can be
+// told apart from "explicitly set to false".
+type BasePermissions struct {
+	Perms  PermFlag
+	SetBit PermFlag
+}
+
+// Set assigns value to permN and marks it as explicitly set.
+func (b *BasePermissions) Set(permN PermFlag, value bool) error {
+	if permN == 0 {
+		return fmt.Errorf("invalid permission flag 0")
+	}
+	b.SetBit |= permN
+	if value {
+		b.Perms |= permN
+	} else {
+		b.Perms &^= permN
+	}
+	return nil
+}
+
+// Unset clears permN's explicitly-set bit, so lookups fall back to the
+// global permissions account again.
+func (b *BasePermissions) Unset(permN PermFlag) error {
+	if permN == 0 {
+		return fmt.Errorf("invalid permission flag 0")
+	}
+	b.SetBit &^= permN
+	b.Perms &^= permN
+	return nil
+}
+
+// IsSet reports whether permN has been explicitly set (to either true or
+// false) on this account.
+func (b BasePermissions) IsSet(permN PermFlag) bool {
+	return b.SetBit&permN > 0
+}
+
+// Get returns permN's value and whether it was explicitly set.
+func (b BasePermissions) Get(permN PermFlag) (value bool, set bool) {
+	return b.Perms&permN > 0, b.IsSet(permN)
+}
+
+// AccountPermissions is the full permission state an account carries: the
+// base permission bitmap plus the RBAC-style roles layered on top of it.
+// The Role base permission bit gates AddRole/RmRole (see vm.AddRolePermission
+// and vm.RmRolePermission); the roles themselves are looked up by plain
+// string equality against Roles.
+type AccountPermissions struct {
+	Base  BasePermissions
+	Roles []string
+}
+
+// HasRole reports whether role has been granted to this account.
+func (ap AccountPermissions) HasRole(role string) bool {
+	for _, r := range ap.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// AddRole grants role to this account, returning true if it was newly
+// added. It returns false without modifying Roles if role is malformed
+// (see ValidRoleName), already held, or the account has already hit
+// MaxRolesPerAccount.
+func (ap *AccountPermissions) AddRole(role string) bool {
+	if !ValidRoleName(role) || ap.HasRole(role) || len(ap.Roles) >= MaxRolesPerAccount {
+		return false
+	}
+	ap.Roles = append(ap.Roles, role)
+	return true
+}
+
+// RmRole revokes role from this account, returning true if it was held.
+func (ap *AccountPermissions) RmRole(role string) bool {
+	for i, r := range ap.Roles {
+		if r == role {
+			ap.Roles = append(ap.Roles[:i], ap.Roles[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ValidRoleName reports whether role is a legal role name: non-empty,
+// plain ASCII, and no longer than MaxRoleNameLength bytes. Names are
+// expected to already be trimmed of the NUL padding a bytes32 ABI argument
+// carries -- see TrimmedString.
+func ValidRoleName(role string) bool {
+	if len(role) == 0 || len(role) > MaxRoleNameLength {
+		return false
+	}
+	for i := 0; i < len(role); i++ {
+		if role[i] > 127 {
+			return false
+		}
+	}
+	return true
+}
+
+// TrimmedString returns w's bytes as a string with trailing NUL padding
+// stripped, the natural decoding for a short name (e.g. a role) packed
+// left-aligned into a fixed-width Word256 ABI argument.
+func TrimmedString(w Word256) string {
+	bz := w.Bytes()
+	i := len(bz)
+	for i > 0 && bz[i-1] == 0 {
+		i--
+	}
+	return string(bz[:i])
+}
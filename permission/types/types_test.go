@@ -0,0 +1,81 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/tendermint/tendermint/common"
+)
+
+func TestAccountPermissionsAddRoleDedupes(t *testing.T) {
+	var ap AccountPermissions
+	if !ap.AddRole("admin") {
+		t.Fatalf("expected first AddRole to succeed")
+	}
+	if ap.AddRole("admin") {
+		t.Fatalf("expected duplicate AddRole to fail")
+	}
+	if len(ap.Roles) != 1 {
+		t.Fatalf("expected exactly one role, got %v", ap.Roles)
+	}
+	if !ap.HasRole("admin") {
+		t.Fatalf("expected HasRole to report the granted role")
+	}
+}
+
+func TestAccountPermissionsAddRoleCapsCount(t *testing.T) {
+	var ap AccountPermissions
+	for i := 0; i < MaxRolesPerAccount; i++ {
+		if !ap.AddRole(fmt.Sprintf("role%d", i)) {
+			t.Fatalf("expected AddRole %d to succeed under the cap", i)
+		}
+	}
+	if ap.AddRole("oneRoleTooMany") {
+		t.Fatalf("expected AddRole to fail once MaxRolesPerAccount is reached")
+	}
+	if len(ap.Roles) != MaxRolesPerAccount {
+		t.Fatalf("expected exactly %d roles, got %d", MaxRolesPerAccount, len(ap.Roles))
+	}
+}
+
+func TestAccountPermissionsRmRole(t *testing.T) {
+	var ap AccountPermissions
+	ap.AddRole("admin")
+	if !ap.RmRole("admin") {
+		t.Fatalf("expected RmRole to report the role was held")
+	}
+	if ap.RmRole("admin") {
+		t.Fatalf("expected RmRole on an absent role to report false")
+	}
+	if ap.HasRole("admin") {
+		t.Fatalf("expected admin to be gone after RmRole")
+	}
+}
+
+func TestValidRoleName(t *testing.T) {
+	cases := []struct {
+		role string
+		want bool
+	}{
+		{"admin", true},
+		{"", false},
+		{strings.Repeat("a", MaxRoleNameLength), true},
+		{strings.Repeat("a", MaxRoleNameLength+1), false},
+		{"caf\xc3\xa9", false}, // non-ASCII
+	}
+	for _, c := range cases {
+		if got := ValidRoleName(c.role); got != c.want {
+			t.Errorf("ValidRoleName(%q) = %v, want %v", c.role, got, c.want)
+		}
+	}
+}
+
+func TestTrimmedString(t *testing.T) {
+	var bz [32]byte
+	copy(bz[:], []byte("admin"))
+	w := Word256(bz)
+	if got := TrimmedString(w); got != "admin" {
+		t.Fatalf("TrimmedString(%X) = %q, want %q", w, got, "admin")
+	}
+}
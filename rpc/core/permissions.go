@@ -0,0 +1,26 @@
+package core
+
+import (
+	"fmt"
+
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	"github.com/tendermint/tendermint/types"
+)
+
+// BroadcastTxPermissions broadcasts an already-signed PermissionsTx through
+// the same mempool path as every other transaction type, so a client can
+// submit permission/role changes without going through the EVM.
+func BroadcastTxPermissions(tx *types.PermissionsTx) (*ctypes.ResultBroadcastTx, error) {
+	return broadcastTx(tx)
+}
+
+// ListAccountRoles returns the roles held by the account at address, so
+// RPC clients can inspect RBAC state without decoding it out of a raw
+// account returned from the regular account query.
+func ListAccountRoles(address []byte) (*ctypes.ResultListAccountRoles, error) {
+	acc := getAccount(address)
+	if acc == nil {
+		return nil, fmt.Errorf("Unknown account %X", address)
+	}
+	return &ctypes.ResultListAccountRoles{Roles: acc.Permissions.Roles}, nil
+}
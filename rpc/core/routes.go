@@ -0,0 +1,17 @@
+package core
+
+import (
+	rpcserver "github.com/tendermint/tendermint/rpc/lib/server"
+)
+
+// Routes registers this package's RPC handlers with the names and
+// positional JSON-RPC param bindings rpcserver.RegisterRPCFuncs expects,
+// the same way every other method on this node's RPC surface is wired
+// in. This snapshot only carries the two methods PermissionsTx added --
+// broadcast_tx_permissions and list_account_roles -- everything else this
+// fork's RPC surface exposes registers its handler the same way, just
+// not in this file.
+var Routes = map[string]*rpcserver.RPCFunc{
+	"broadcast_tx_permissions": rpcserver.NewRPCFunc(BroadcastTxPermissions, "tx"),
+	"list_account_roles":       rpcserver.NewRPCFunc(ListAccountRoles, "address"),
+}
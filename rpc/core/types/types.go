@@ -0,0 +1,20 @@
+// Package core_types defines the JSON result types the rpc/core handlers
+// return, so RPC clients get a stable, documented response shape instead
+// of whatever internal type a handler happens to compute.
+package core_types
+
+// ResultBroadcastTx is returned by the BroadcastTx* RPC methods: the
+// result of running the submitted tx through CheckTx immediately after
+// it's added to the mempool.
+type ResultBroadcastTx struct {
+	Code uint32 `json:"code"`
+	Data []byte `json:"data"`
+	Log  string `json:"log"`
+}
+
+// ResultListAccountRoles is returned by ListAccountRoles: the full set of
+// RBAC roles currently held by an account, so RPC clients don't have to
+// decode them out of a raw account query.
+type ResultListAccountRoles struct {
+	Roles []string `json:"roles"`
+}
@@ -0,0 +1,91 @@
+package state
+
+import (
+	"fmt"
+
+	. "github.com/tendermint/tendermint/common"
+	ptypes "github.com/tendermint/tendermint/permission/types"
+	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/vm"
+)
+
+// ExecPermissionsTx validates and applies a PermissionsTx: it checks the
+// sender's sequence number and fee balance exactly like the other tx
+// types, then dispatches the permission mutation to the same vm.*Permission
+// helpers the SNative EVM path calls, so the two routes can never drift
+// out of lockstep.
+func ExecPermissionsTx(appState vm.AppState, tx *types.PermissionsTx) error {
+	addr := LeftPadWord256(tx.Input.Address)
+	inAcc := appState.GetAccount(addr)
+	if inAcc == nil {
+		return fmt.Errorf("Invalid address %X from PermissionsTx", tx.Input.Address)
+	}
+	if inAcc.Balance < tx.Input.Amount {
+		return fmt.Errorf("Insufficient funds %X to pay PermissionsTx fee", tx.Input.Address)
+	}
+	if tx.Input.Sequence != inAcc.Sequence+1 {
+		return fmt.Errorf("Invalid sequence %d for PermissionsTx, expected %d", tx.Input.Sequence, inAcc.Sequence+1)
+	}
+	inAcc.Sequence++
+	inAcc.Balance -= tx.Input.Amount
+	appState.UpdateAccount(inAcc)
+
+	return execPermArgs(appState, inAcc, tx.PermArgs)
+}
+
+// execPermArgs enforces the same per-operation permission vm/snative.go
+// checks before CALLing a snative -- a PermissionsTx is just the non-EVM
+// entry point to the same mutation, so it must be gated identically or a
+// funded account could grant itself permissions through it.
+func execPermArgs(appState vm.AppState, caller *vm.Account, args types.PermArgs) error {
+	numSet := 0
+	for _, isSet := range []bool{args.HasBase != nil, args.SetBase != nil, args.UnsetBase != nil,
+		args.SetGlobal != nil, args.HasRole != nil, args.AddRole != nil, args.RmRole != nil} {
+		if isSet {
+			numSet++
+		}
+	}
+	if numSet > 1 {
+		return fmt.Errorf("PermissionsTx carried %d permission args, expected exactly one", numSet)
+	}
+	switch {
+	case args.SetBase != nil:
+		if !vm.HasPermission(appState, caller, ptypes.SetBase) {
+			return vm.ErrInvalidPermission{Address: caller.Address, SNative: "SetBase"}
+		}
+		a := args.SetBase
+		return vm.SetBasePermission(appState, LeftPadWord256(a.Address), a.Permission, a.Value)
+	case args.UnsetBase != nil:
+		if !vm.HasPermission(appState, caller, ptypes.UnsetBase) {
+			return vm.ErrInvalidPermission{Address: caller.Address, SNative: "UnsetBase"}
+		}
+		a := args.UnsetBase
+		return vm.UnsetBasePermission(appState, LeftPadWord256(a.Address), a.Permission)
+	case args.SetGlobal != nil:
+		if !vm.HasPermission(appState, caller, ptypes.SetGlobal) {
+			return vm.ErrInvalidPermission{Address: caller.Address, SNative: "SetGlobal"}
+		}
+		a := args.SetGlobal
+		return vm.SetGlobalPermission(appState, a.Permission, a.Value)
+	case args.AddRole != nil:
+		if !vm.HasPermission(appState, caller, ptypes.AddRole) {
+			return vm.ErrInvalidPermission{Address: caller.Address, SNative: "AddRole"}
+		}
+		a := args.AddRole
+		_, err := vm.AddRolePermission(appState, caller, LeftPadWord256(a.Address), a.Role)
+		return err
+	case args.RmRole != nil:
+		if !vm.HasPermission(appState, caller, ptypes.RmRole) {
+			return vm.ErrInvalidPermission{Address: caller.Address, SNative: "RmRole"}
+		}
+		a := args.RmRole
+		_, err := vm.RmRolePermission(appState, caller, LeftPadWord256(a.Address), a.Role)
+		return err
+	case args.HasBase != nil, args.HasRole != nil:
+		// Pure reads: nothing to mutate. Clients that want the answer
+		// should use the RPC query path rather than broadcasting a tx.
+		return nil
+	default:
+		return fmt.Errorf("PermissionsTx carried no permission args")
+	}
+}
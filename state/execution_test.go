@@ -0,0 +1,119 @@
+package state
+
+import (
+	"testing"
+
+	. "github.com/tendermint/tendermint/common"
+	ptypes "github.com/tendermint/tendermint/permission/types"
+	"github.com/tendermint/tendermint/types"
+	"github.com/tendermint/tendermint/vm"
+)
+
+// fakeAppState is the minimal vm.AppState execPermArgs needs: somewhere to
+// look accounts up and write them back to.
+type fakeAppState struct {
+	accounts map[Word256]*vm.Account
+}
+
+func newFakeAppState(accs ...*vm.Account) *fakeAppState {
+	s := &fakeAppState{accounts: make(map[Word256]*vm.Account)}
+	for _, acc := range accs {
+		s.accounts[acc.Address] = acc
+	}
+	return s
+}
+
+func (s *fakeAppState) GetAccount(addr Word256) *vm.Account {
+	return s.accounts[addr]
+}
+
+func (s *fakeAppState) UpdateAccount(acc *vm.Account) {
+	s.accounts[acc.Address] = acc
+}
+
+func TestExecPermArgsRequiresPermission(t *testing.T) {
+	target := &vm.Account{Address: LeftPadWord256([]byte("target"))}
+	caller := &vm.Account{Address: LeftPadWord256([]byte("caller"))}
+
+	for _, args := range []types.PermArgs{
+		{SetBase: &types.SetBaseArgs{Address: target.Address.Postfix(20), Permission: ptypes.Send, Value: true}},
+		{UnsetBase: &types.UnsetBaseArgs{Address: target.Address.Postfix(20), Permission: ptypes.Send}},
+		{SetGlobal: &types.SetGlobalArgs{Permission: ptypes.Send, Value: true}},
+		{AddRole: &types.AddRoleArgs{Address: target.Address.Postfix(20), Role: "admin"}},
+		{RmRole: &types.RmRoleArgs{Address: target.Address.Postfix(20), Role: "admin"}},
+	} {
+		appState := newFakeAppState(target, caller)
+		if err := execPermArgs(appState, caller, args); err == nil {
+			t.Fatalf("expected an unprivileged caller to be rejected for %+v", args)
+		} else if _, ok := err.(vm.ErrInvalidPermission); !ok {
+			t.Fatalf("expected vm.ErrInvalidPermission for %+v, got %v", args, err)
+		}
+		if target.Permissions.Base.IsSet(ptypes.Send) {
+			t.Fatalf("unprivileged caller must not be able to mutate permissions via %+v", args)
+		}
+		if target.Permissions.HasRole("admin") {
+			t.Fatalf("unprivileged caller must not be able to grant roles via %+v", args)
+		}
+	}
+}
+
+// TestExecPermArgsAddRoleRequiresSNativePermission covers the gap that let
+// AddRole/RmRole slip past the SetBase/UnsetBase/SetGlobal gating fixed
+// earlier: a caller holding only the separate Role base permission (which
+// vm.AddRolePermission checks internally) must still be rejected for
+// lacking the snative-level AddRole permission that vm/snative.go's addRole
+// enforces for the same call made through the EVM.
+func TestExecPermArgsAddRoleRequiresSNativePermission(t *testing.T) {
+	target := &vm.Account{Address: LeftPadWord256([]byte("target"))}
+	caller := &vm.Account{Address: LeftPadWord256([]byte("caller"))}
+	if err := caller.Permissions.Base.Set(ptypes.Role, true); err != nil {
+		t.Fatalf("failed to grant Role permission on test caller: %v", err)
+	}
+	appState := newFakeAppState(target, caller)
+
+	args := types.PermArgs{AddRole: &types.AddRoleArgs{Address: target.Address.Postfix(20), Role: "admin"}}
+	err := execPermArgs(appState, caller, args)
+	if _, ok := err.(vm.ErrInvalidPermission); !ok {
+		t.Fatalf("expected vm.ErrInvalidPermission for a caller missing AddRole, got %v", err)
+	}
+	if target.Permissions.HasRole("admin") {
+		t.Fatalf("caller missing AddRole must not be able to grant roles even while holding Role")
+	}
+}
+
+func TestExecPermArgsSetBasePermittedCaller(t *testing.T) {
+	target := &vm.Account{Address: LeftPadWord256([]byte("target"))}
+	caller := &vm.Account{Address: LeftPadWord256([]byte("caller"))}
+	if err := caller.Permissions.Base.Set(ptypes.SetBase, true); err != nil {
+		t.Fatalf("failed to grant permission on test caller: %v", err)
+	}
+	appState := newFakeAppState(target, caller)
+
+	args := types.PermArgs{SetBase: &types.SetBaseArgs{Address: target.Address.Postfix(20), Permission: ptypes.Send, Value: true}}
+	if err := execPermArgs(appState, caller, args); err != nil {
+		t.Fatalf("unexpected error from permitted caller: %v", err)
+	}
+	if !target.Permissions.Base.IsSet(ptypes.Send) {
+		t.Fatalf("expected target to have Send permission set")
+	}
+}
+
+func TestExecPermArgsAddRolePermittedCaller(t *testing.T) {
+	target := &vm.Account{Address: LeftPadWord256([]byte("target"))}
+	caller := &vm.Account{Address: LeftPadWord256([]byte("caller"))}
+	if err := caller.Permissions.Base.Set(ptypes.AddRole, true); err != nil {
+		t.Fatalf("failed to grant AddRole permission on test caller: %v", err)
+	}
+	if err := caller.Permissions.Base.Set(ptypes.Role, true); err != nil {
+		t.Fatalf("failed to grant Role permission on test caller: %v", err)
+	}
+	appState := newFakeAppState(target, caller)
+
+	args := types.PermArgs{AddRole: &types.AddRoleArgs{Address: target.Address.Postfix(20), Role: "admin"}}
+	if err := execPermArgs(appState, caller, args); err != nil {
+		t.Fatalf("unexpected error from permitted caller: %v", err)
+	}
+	if !target.Permissions.HasRole("admin") {
+		t.Fatalf("expected target to hold the admin role")
+	}
+}
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"encoding/json"
+
+	crypto "github.com/tendermint/go-crypto"
+	ptypes "github.com/tendermint/tendermint/permission/types"
+)
+
+// TxInput is the signed sender half of a transaction: who is paying the
+// fee, their sequence number, and their signature over the rest of the tx.
+type TxInput struct {
+	Address   []byte           `json:"address"`
+	Amount    uint64           `json:"amount"`
+	Sequence  int              `json:"sequence"`
+	Signature crypto.Signature `json:"signature"`
+	PubKey    crypto.PubKey    `json:"pub_key,omitempty"`
+}
+
+// PermissionsTx lets a validator mutate an account's permissions or roles
+// directly, without routing the change through an SNative call in the EVM.
+// Input.Amount is still charged as the usual tx fee; state.ExecPermissionsTx
+// shares its mutation logic with vm's snatives so both routes stay in
+// lockstep.
+type PermissionsTx struct {
+	Input    *TxInput `json:"input"`
+	PermArgs PermArgs `json:"args"`
+}
+
+// PermArgs is a union of the permission mutations a PermissionsTx can
+// carry. Exactly one field should be non-nil; state.ExecPermissionsTx
+// validates this and dispatches on whichever is set.
+type PermArgs struct {
+	HasBase   *HasBaseArgs   `json:"has_base,omitempty"`
+	SetBase   *SetBaseArgs   `json:"set_base,omitempty"`
+	UnsetBase *UnsetBaseArgs `json:"unset_base,omitempty"`
+	SetGlobal *SetGlobalArgs `json:"set_global,omitempty"`
+	HasRole   *HasRoleArgs   `json:"has_role,omitempty"`
+	AddRole   *AddRoleArgs   `json:"add_role,omitempty"`
+	RmRole    *RmRoleArgs    `json:"rm_role,omitempty"`
+}
+
+type HasBaseArgs struct {
+	Address    []byte          `json:"address"`
+	Permission ptypes.PermFlag `json:"permission"`
+}
+
+type SetBaseArgs struct {
+	Address    []byte          `json:"address"`
+	Permission ptypes.PermFlag `json:"permission"`
+	Value      bool            `json:"value"`
+}
+
+type UnsetBaseArgs struct {
+	Address    []byte          `json:"address"`
+	Permission ptypes.PermFlag `json:"permission"`
+}
+
+type SetGlobalArgs struct {
+	Permission ptypes.PermFlag `json:"permission"`
+	Value      bool            `json:"value"`
+}
+
+type HasRoleArgs struct {
+	Address []byte `json:"address"`
+	Role    string `json:"role"`
+}
+
+type AddRoleArgs struct {
+	Address []byte `json:"address"`
+	Role    string `json:"role"`
+}
+
+type RmRoleArgs struct {
+	Address []byte `json:"address"`
+	Role    string `json:"role"`
+}
+
+// SignBytes returns the canonical bytes a signer signs over: the tx with
+// its Input.Signature cleared, so the signature doesn't sign over itself.
+func (tx *PermissionsTx) SignBytes() []byte {
+	sigless := *tx
+	input := *tx.Input
+	input.Signature = nil
+	sigless.Input = &input
+	bz, err := json.Marshal(sigless)
+	if err != nil {
+		panic(err)
+	}
+	return bz
+}
+
+// Sign attaches pubkey and sig to the tx's Input, fulfilling keys.Signable
+// so cryptostore.Manager.Sign can sign a PermissionsTx like any other key
+// holder's transaction.
+func (tx *PermissionsTx) Sign(pubkey crypto.PubKey, sig crypto.Signature) error {
+	tx.Input.PubKey = pubkey
+	tx.Input.Signature = sig
+	return nil
+}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"fmt"
+
+	keys "github.com/tendermint/go-crypto/keys"
+)
+
+// NewPermissionsTx builds an unsigned PermissionsTx debiting fee from
+// address at the given sequence number.
+func NewPermissionsTx(address []byte, sequence int, fee uint64, args PermArgs) *PermissionsTx {
+	return &PermissionsTx{
+		Input: &TxInput{
+			Address:  address,
+			Amount:   fee,
+			Sequence: sequence,
+		},
+		PermArgs: args,
+	}
+}
+
+// SignPermissionsTx signs tx with the key stored under name/passphrase,
+// mirroring the SignFooTx helpers for this package's other transaction
+// types.
+func SignPermissionsTx(signer keys.Signer, name, passphrase string, tx *PermissionsTx) error {
+	if err := signer.Sign(name, passphrase, tx); err != nil {
+		return fmt.Errorf("could not sign PermissionsTx: %v", err)
+	}
+	return nil
+}
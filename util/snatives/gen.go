@@ -0,0 +1,56 @@
+// Package snatives generates a Solidity interface from the registered
+// SNative contracts, so contract authors can write
+//
+//	import "SNatives.sol";
+//	...
+//	permissions.setBase(addr, flag, true);
+//
+// instead of hand-assembling the ABI-encoded CALL data for a snative.
+package snatives
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/tendermint/tendermint/vm"
+)
+
+const solidityHeader = "// Code generated by util/snatives. DO NOT EDIT.\npragma solidity >=0.4.0;\n"
+
+// WriteSolidityInterface renders every contract in vm.RegisteredSNativeContracts
+// as a function declaration on a single `Permissions` interface, in
+// deterministic (signature) order.
+func WriteSolidityInterface(w io.Writer) error {
+	signatures := make([]string, 0, len(vm.RegisteredSNativeContracts))
+	for _, si := range vm.RegisteredSNativeContracts {
+		signatures = append(signatures, si.Signature)
+	}
+	sort.Strings(signatures)
+
+	if _, err := io.WriteString(w, solidityHeader); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, "\ninterface Permissions {\n"); err != nil {
+		return err
+	}
+	for _, signature := range signatures {
+		if _, err := fmt.Fprintf(w, "    function %s external returns (bool);\n", signature); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "}\n")
+	return err
+}
+
+// GenerateFile writes the generated interface to path, creating or
+// truncating it as needed.
+func GenerateFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return WriteSolidityInterface(f)
+}
@@ -0,0 +1,84 @@
+package vm
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"golang.org/x/crypto/sha3"
+
+	. "github.com/tendermint/tendermint/common"
+)
+
+// selector is the first four bytes of the keccak256 hash of a function's
+// Solidity signature, exactly as the EVM ABI encodes a CALL's function
+// selector. Registered snatives are indexed by this so a Solidity contract
+// can call them like any other contract method.
+type selector [4]byte
+
+func selectorFor(signature string) selector {
+	hasher := sha3.NewKeccak256()
+	hasher.Write([]byte(signature))
+	sum := hasher.Sum(nil)
+	var sel selector
+	copy(sel[:], sum[:4])
+	return sel
+}
+
+func (s selector) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// abiType enumerates the Solidity argument types the snative ABI decoder
+// understands. It is deliberately small: snatives only ever need to move
+// addresses, permission flags, booleans and role names across the EVM
+// boundary, and every one of those is a single 32-byte ABI word, so there
+// is no dynamic type (like Solidity's string) to support.
+type abiType int
+
+const (
+	abiAddress abiType = iota
+	abiUint64
+	abiBool
+	abiBytes32
+)
+
+func (t abiType) solidity() string {
+	switch t {
+	case abiAddress:
+		return "address"
+	case abiUint64:
+		return "uint64"
+	case abiBool:
+		return "bool"
+	case abiBytes32:
+		return "bytes32"
+	default:
+		PanicSanity("snative ABI: unknown abiType")
+		return ""
+	}
+}
+
+// decodeArgs ABI-decodes input (calldata with the 4-byte selector already
+// stripped) against types, returning one Word256 per entry in the same
+// order types lists them. Since abiType is always a single fixed-width
+// word, decoding is just bounds-checking input is long enough and
+// slicing it out -- there's no head/tail indirection to resolve.
+func decodeArgs(input []byte, types []abiType) ([]Word256, error) {
+	args := make([]Word256, len(types))
+	for i := range types {
+		if len(input) < (i+1)*32 {
+			return nil, fmt.Errorf("snative ABI: input too short for argument %d", i)
+		}
+		args[i] = LeftPadWord256(input[i*32 : (i+1)*32])
+	}
+	return args, nil
+}
+
+// encodeBool ABI-encodes a boolean return value as a uint256 0 or 1, the
+// same convention solc generates for a `returns (bool)` function.
+func encodeBool(b bool) []byte {
+	if b {
+		return LeftPadWord256([]byte{0x1}).Bytes()
+	}
+	return LeftPadWord256([]byte{0x0}).Bytes()
+}
@@ -0,0 +1,34 @@
+package vm
+
+import (
+	"testing"
+
+	. "github.com/tendermint/tendermint/common"
+)
+
+func TestDecodeArgs(t *testing.T) {
+	var addr Word256
+	copy(addr[:], []byte("target"))
+	input := append(append([]byte{}, addr.Bytes()...), Uint64ToWord256(uint64(7)).Bytes()...)
+
+	args, err := decodeArgs(input, []abiType{abiAddress, abiUint64})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(args) != 2 {
+		t.Fatalf("expected 2 decoded args, got %d", len(args))
+	}
+	if args[0] != addr {
+		t.Fatalf("expected first arg to decode to %X, got %X", addr, args[0])
+	}
+	if Uint64FromWord256(args[1]) != 7 {
+		t.Fatalf("expected second arg to decode to 7, got %d", Uint64FromWord256(args[1]))
+	}
+}
+
+func TestDecodeArgsTooShort(t *testing.T) {
+	input := make([]byte, 32)
+	if _, err := decodeArgs(input, []abiType{abiAddress, abiUint64}); err == nil {
+		t.Fatalf("expected an error when input is too short for the requested argument types")
+	}
+}
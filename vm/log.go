@@ -0,0 +1,21 @@
+package vm
+
+import "github.com/inconshreveable/log15"
+
+// logger receives the VM's structured traces -- snative calls, in
+// particular. It discards everything until the embedding application
+// wires in its own handler via SetLogger, so the package stays silent by
+// default.
+var logger = log15.New("module", "vm")
+
+func init() {
+	logger.SetHandler(log15.DiscardHandler())
+}
+
+// SetLogger injects a log15.Logger the VM should trace snative calls
+// (and, over time, other VM-level events) through, so an embedding
+// application can filter or redirect those traces at runtime instead of
+// the package printing straight to stderr.
+func SetLogger(l log15.Logger) {
+	logger = l
+}
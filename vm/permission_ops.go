@@ -0,0 +1,132 @@
+package vm
+
+import (
+	"fmt"
+
+	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/events"
+	ptypes "github.com/tendermint/tendermint/permission/types"
+)
+
+// The functions below are the actual state mutations behind each snative.
+// They are shared with state.ExecPermissionsTx so that granting/revoking a
+// permission or role via a PermissionsTx and via the `SNatives.sol`
+// snative contracts can never drift out of lockstep with one another.
+
+// firePermissionsEvent emits events.EventStringPermissions(addr) when
+// appState also implements events.Fireable, so RPC clients subscribed to
+// the event stream see a permission or role change made through either
+// entry point.
+func firePermissionsEvent(appState AppState, addr Word256) {
+	fireable, ok := appState.(events.Fireable)
+	if !ok {
+		return
+	}
+	fireable.FireEvent(events.EventStringPermissions(addr), events.EventDataPermissions{Address: addr})
+}
+
+func HasBasePermission(appState AppState, addr Word256, permN ptypes.PermFlag) (bool, error) {
+	vmAcc := appState.GetAccount(addr)
+	if vmAcc == nil {
+		return false, fmt.Errorf("Unknown account %X", addr)
+	}
+	if !ValidPermN(permN) {
+		return false, ptypes.ErrInvalidPermission(permN)
+	}
+	return HasPermission(appState, vmAcc, permN), nil
+}
+
+func SetBasePermission(appState AppState, addr Word256, permN ptypes.PermFlag, value bool) error {
+	vmAcc := appState.GetAccount(addr)
+	if vmAcc == nil {
+		return fmt.Errorf("Unknown account %X", addr)
+	}
+	if !ValidPermN(permN) {
+		return ptypes.ErrInvalidPermission(permN)
+	}
+	if err := vmAcc.Permissions.Base.Set(permN, value); err != nil {
+		return err
+	}
+	appState.UpdateAccount(vmAcc)
+	firePermissionsEvent(appState, addr)
+	return nil
+}
+
+func UnsetBasePermission(appState AppState, addr Word256, permN ptypes.PermFlag) error {
+	vmAcc := appState.GetAccount(addr)
+	if vmAcc == nil {
+		return fmt.Errorf("Unknown account %X", addr)
+	}
+	if !ValidPermN(permN) {
+		return ptypes.ErrInvalidPermission(permN)
+	}
+	if err := vmAcc.Permissions.Base.Unset(permN); err != nil {
+		return err
+	}
+	appState.UpdateAccount(vmAcc)
+	firePermissionsEvent(appState, addr)
+	return nil
+}
+
+func SetGlobalPermission(appState AppState, permN ptypes.PermFlag, value bool) error {
+	vmAcc := appState.GetAccount(ptypes.GlobalPermissionsAddress256)
+	if vmAcc == nil {
+		PanicSanity("cant find the global permissions account")
+	}
+	if !ValidPermN(permN) {
+		return ptypes.ErrInvalidPermission(permN)
+	}
+	if err := vmAcc.Permissions.Base.Set(permN, value); err != nil {
+		return err
+	}
+	appState.UpdateAccount(vmAcc)
+	firePermissionsEvent(appState, ptypes.GlobalPermissionsAddress256)
+	return nil
+}
+
+func HasRolePermission(appState AppState, addr Word256, role string) (bool, error) {
+	vmAcc := appState.GetAccount(addr)
+	if vmAcc == nil {
+		return false, fmt.Errorf("Unknown account %X", addr)
+	}
+	return vmAcc.Permissions.HasRole(role), nil
+}
+
+// AddRolePermission grants role to addr on behalf of caller. caller must
+// hold the Role base permission (directly, or via GlobalPermissionsAddress256)
+// -- this is checked here rather than only at the snative call site so that
+// state.ExecPermissionsTx gets the same RBAC enforcement as a `SNatives.sol`
+// call.
+func AddRolePermission(appState AppState, caller *Account, addr Word256, role string) (bool, error) {
+	if !HasPermission(appState, caller, ptypes.Role) {
+		return false, fmt.Errorf("account %X does not have Role permission", caller.Address)
+	}
+	vmAcc := appState.GetAccount(addr)
+	if vmAcc == nil {
+		return false, fmt.Errorf("Unknown account %X", addr)
+	}
+	added := vmAcc.Permissions.AddRole(role)
+	if added {
+		appState.UpdateAccount(vmAcc)
+		firePermissionsEvent(appState, addr)
+	}
+	return added, nil
+}
+
+// RmRolePermission revokes role from addr on behalf of caller; see
+// AddRolePermission for the Role permission requirement.
+func RmRolePermission(appState AppState, caller *Account, addr Word256, role string) (bool, error) {
+	if !HasPermission(appState, caller, ptypes.Role) {
+		return false, fmt.Errorf("account %X does not have Role permission", caller.Address)
+	}
+	vmAcc := appState.GetAccount(addr)
+	if vmAcc == nil {
+		return false, fmt.Errorf("Unknown account %X", addr)
+	}
+	removed := vmAcc.Permissions.RmRole(role)
+	if removed {
+		appState.UpdateAccount(vmAcc)
+		firePermissionsEvent(appState, addr)
+	}
+	return removed, nil
+}
@@ -0,0 +1,173 @@
+package vm
+
+import (
+	"testing"
+
+	. "github.com/tendermint/tendermint/common"
+	"github.com/tendermint/tendermint/events"
+	ptypes "github.com/tendermint/tendermint/permission/types"
+)
+
+// fakeAppState is the minimal AppState a snative needs: somewhere to look
+// accounts up and write them back to.
+type fakeAppState struct {
+	accounts map[Word256]*Account
+}
+
+func newFakeAppState(accs ...*Account) *fakeAppState {
+	s := &fakeAppState{accounts: make(map[Word256]*Account)}
+	for _, acc := range accs {
+		s.accounts[acc.Address] = acc
+	}
+	return s
+}
+
+func (s *fakeAppState) GetAccount(addr Word256) *Account {
+	return s.accounts[addr]
+}
+
+func (s *fakeAppState) UpdateAccount(acc *Account) {
+	s.accounts[acc.Address] = acc
+}
+
+func permittedCaller(t *testing.T, flag ptypes.PermFlag) *Account {
+	caller := &Account{Address: LeftPadWord256([]byte("caller"))}
+	if err := caller.Permissions.Base.Set(flag, true); err != nil {
+		t.Fatalf("failed to grant permission on test caller: %v", err)
+	}
+	return caller
+}
+
+func forbiddenCaller() *Account {
+	return &Account{Address: LeftPadWord256([]byte("caller"))}
+}
+
+func TestSetBasePermRequiresPermission(t *testing.T) {
+	target := &Account{Address: LeftPadWord256([]byte("target"))}
+	args := []Word256{target.Address, Uint64ToWord256(uint64(ptypes.Send)), LeftPadWord256([]byte{0x1})}
+
+	t.Run("forbidden caller", func(t *testing.T) {
+		appState := newFakeAppState(target)
+		gas := int64(1000)
+		_, err := setBasePerm(appState, forbiddenCaller(), args, &gas)
+		if _, ok := err.(ErrInvalidPermission); !ok {
+			t.Fatalf("expected ErrInvalidPermission, got %v", err)
+		}
+	})
+
+	t.Run("permitted caller", func(t *testing.T) {
+		appState := newFakeAppState(target)
+		caller := permittedCaller(t, ptypes.SetBase)
+		appState.UpdateAccount(caller)
+		gas := int64(1000)
+		out, err := setBasePerm(appState, caller, args, &gas)
+		if err != nil {
+			t.Fatalf("unexpected error from permitted caller: %v", err)
+		}
+		if !HasPermission(appState, target, ptypes.Send) {
+			t.Fatalf("expected target to have Send permission set")
+		}
+		if len(out) == 0 {
+			t.Fatalf("expected non-empty ABI-encoded return value")
+		}
+		if gas != 1000-gasCostSetBase {
+			t.Fatalf("expected gas to be charged gasCostSetBase, got %d remaining", gas)
+		}
+	})
+}
+
+func TestSetBasePermRequiresGas(t *testing.T) {
+	target := &Account{Address: LeftPadWord256([]byte("target"))}
+	args := []Word256{target.Address, Uint64ToWord256(uint64(ptypes.Send)), LeftPadWord256([]byte{0x1})}
+
+	appState := newFakeAppState(target)
+	caller := permittedCaller(t, ptypes.SetBase)
+	appState.UpdateAccount(caller)
+
+	gas := gasCostSetBase - 1
+	_, err := setBasePerm(appState, caller, args, &gas)
+	if err != ErrInsufficientGas {
+		t.Fatalf("expected ErrInsufficientGas, got %v", err)
+	}
+}
+
+func TestAddRolePermRequiresRolePermission(t *testing.T) {
+	target := &Account{Address: LeftPadWord256([]byte("target"))}
+	var roleArg Word256
+	copy(roleArg[:], []byte("admin"))
+	args := []Word256{target.Address, roleArg}
+
+	t.Run("snative permission without Role permission", func(t *testing.T) {
+		appState := newFakeAppState(target)
+		caller := permittedCaller(t, ptypes.AddRole)
+		appState.UpdateAccount(caller)
+		gas := int64(1000)
+		_, err := addRole(appState, caller, args, &gas)
+		if err == nil {
+			t.Fatalf("expected error when caller lacks the Role base permission")
+		}
+	})
+
+	t.Run("snative and Role permission both held", func(t *testing.T) {
+		appState := newFakeAppState(target)
+		caller := permittedCaller(t, ptypes.AddRole)
+		if err := caller.Permissions.Base.Set(ptypes.Role, true); err != nil {
+			t.Fatalf("failed to grant Role permission on test caller: %v", err)
+		}
+		appState.UpdateAccount(caller)
+
+		gas := int64(1000)
+		out, err := addRole(appState, caller, args, &gas)
+		if err != nil {
+			t.Fatalf("unexpected error from permitted caller: %v", err)
+		}
+		if !target.Permissions.HasRole("admin") {
+			t.Fatalf("expected target to hold the admin role")
+		}
+		if len(out) == 0 {
+			t.Fatalf("expected non-empty ABI-encoded return value")
+		}
+	})
+}
+
+// fakeFireable records every event fired through it, so tests can assert
+// a snative mutation published events.EventStringPermissions.
+type fakeFireable struct {
+	events []string
+}
+
+func (f *fakeFireable) FireEvent(event string, data events.EventData) {
+	f.events = append(f.events, event)
+}
+
+// fireableAppState pairs fakeAppState with a fakeFireable, so it also
+// satisfies events.Fireable for firePermissionsEvent to find via type
+// assertion.
+type fireableAppState struct {
+	*fakeAppState
+	*fakeFireable
+}
+
+func TestAddRolePermFiresPermissionsEvent(t *testing.T) {
+	target := &Account{Address: LeftPadWord256([]byte("target"))}
+	var roleArg Word256
+	copy(roleArg[:], []byte("admin"))
+	args := []Word256{target.Address, roleArg}
+
+	appState := &fireableAppState{fakeAppState: newFakeAppState(target), fakeFireable: &fakeFireable{}}
+	caller := permittedCaller(t, ptypes.AddRole)
+	if err := caller.Permissions.Base.Set(ptypes.Role, true); err != nil {
+		t.Fatalf("failed to grant Role permission on test caller: %v", err)
+	}
+	appState.UpdateAccount(caller)
+
+	gas := int64(1000)
+	if _, err := addRole(appState, caller, args, &gas); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := events.EventStringPermissions(target.Address)
+	if len(appState.events) != 1 || appState.events[0] != want {
+		t.Fatalf("expected a single %q event, got %v", want, appState.events)
+	}
+}
@@ -1,67 +1,129 @@
 package vm
 
 import (
+	"errors"
 	"fmt"
 
 	. "github.com/tendermint/tendermint/common"
 	ptypes "github.com/tendermint/tendermint/permission/types"
 )
 
+// Per-operation gas costs for the registered snatives, mirroring how the
+// VM's opcode loop meters EVM instructions. Reads are cheaper than the
+// mutations, which additionally pay for the permissions event they fire.
+const (
+	gasCostHasBase   int64 = 1
+	gasCostSetBase   int64 = 5
+	gasCostUnsetBase int64 = 5
+	gasCostSetGlobal int64 = 5
+	gasCostHasRole   int64 = 1
+	gasCostAddRole   int64 = 5
+	gasCostRmRole    int64 = 5
+)
+
+// ErrInsufficientGas is returned by a snative when charging its GasCost
+// would take *gas negative.
+var ErrInsufficientGas = errors.New("insufficient gas for snative call")
+
+// chargeGas subtracts cost from *gas, the same underflow check the VM's
+// opcode loop applies to EVM gas, so a snative call can't run for free.
+func chargeGas(gas *int64, cost int64) error {
+	if *gas < cost {
+		return ErrInsufficientGas
+	}
+	*gas -= cost
+	return nil
+}
+
 type snativeInfo struct {
-	PermFlag   ptypes.PermFlag
-	NArgs      int
+	// Name is the capitalised permission name permission/types knows about,
+	// e.g. "SetBase".
+	Name string
+	// Signature is the Solidity-style function signature that selectorFor
+	// hashes into a selector and util/snatives renders as an interface
+	// declaration, e.g. "setBase(address,uint64,bool)".
+	Signature string
+	Args      []abiType
+	PermFlag  ptypes.PermFlag
+	// GasCost is the amount Executable charges against the caller's gas
+	// budget: registerSNativeContracts fills it from the same gasCost*
+	// constant each snative's own chargeGas call uses. CallSNative does
+	// not read it.
+	GasCost    int64
 	ArgsError  error
 	Executable SNativeContract
 }
 
-// Takes an appState so it can lookup/update accounts,
-// and an input byte array containing at least one Word256
-// TODO: ABI
-type SNativeContract func(appState AppState, input []byte) (output []byte, err error)
+// Takes an appState so it can lookup/update accounts, the caller so it can
+// enforce the relevant permission against them, the ABI-decoded arguments
+// (one Word256 per entry in the snativeInfo's Args, already validated and
+// sliced out of the call's calldata by decodeArgs), and the caller's
+// remaining gas, which the snative must charge GasCost against.
+type SNativeContract func(appState AppState, caller *Account, args []Word256, gas *int64) (output []byte, err error)
 
 //------------------------------------------------------------------------------------------------
 // Registered SNative contracts
 
-var RegisteredSNativeContracts = make(map[Word256]*snativeInfo)
+// RegisteredSNativeContracts is keyed by the 4-byte selector Solidity would
+// compute for each snative's signature (keccak256(signature)[:4]), so
+// CALLing a snative from a Solidity contract looks exactly like calling any
+// other contract method.
+var RegisteredSNativeContracts = make(map[selector]*snativeInfo)
 
 func registerSNativeContracts() {
-	RegisteredSNativeContracts[LeftPadWord256([]byte("HasBase"))] = getSNativeInfo("HasBase")
-	RegisteredSNativeContracts[LeftPadWord256([]byte("SetBase"))] = getSNativeInfo("SetBase")
-	RegisteredSNativeContracts[LeftPadWord256([]byte("UnsetBase"))] = getSNativeInfo("UnsetBase")
-	RegisteredSNativeContracts[LeftPadWord256([]byte("SetGlobal"))] = getSNativeInfo("SetGlobal")
-	RegisteredSNativeContracts[LeftPadWord256([]byte("HasRole"))] = getSNativeInfo("HasRole")
-	RegisteredSNativeContracts[LeftPadWord256([]byte("AddRole"))] = getSNativeInfo("AddRole")
-	RegisteredSNativeContracts[LeftPadWord256([]byte("RmRole"))] = getSNativeInfo("RmRole")
+	for _, si := range []*snativeInfo{
+		newSNativeInfo("HasBase", "hasBase(address,uint64)", []abiType{abiAddress, abiUint64}, gasCostHasBase, hasBasePerm),
+		newSNativeInfo("SetBase", "setBase(address,uint64,bool)", []abiType{abiAddress, abiUint64, abiBool}, gasCostSetBase, setBasePerm),
+		newSNativeInfo("UnsetBase", "unsetBase(address,uint64)", []abiType{abiAddress, abiUint64}, gasCostUnsetBase, unsetBasePerm),
+		newSNativeInfo("SetGlobal", "setGlobal(uint64,bool)", []abiType{abiUint64, abiBool}, gasCostSetGlobal, setGlobalPerm),
+		newSNativeInfo("HasRole", "hasRole(address,bytes32)", []abiType{abiAddress, abiBytes32}, gasCostHasRole, hasRole),
+		newSNativeInfo("AddRole", "addRole(address,bytes32)", []abiType{abiAddress, abiBytes32}, gasCostAddRole, addRole),
+		newSNativeInfo("RmRole", "rmRole(address,bytes32)", []abiType{abiAddress, abiBytes32}, gasCostRmRole, rmRole),
+	} {
+		RegisteredSNativeContracts[selectorFor(si.Signature)] = si
+	}
 }
 
-// sets the number of arguments, a friendly error message, and the snative function ("executable")
-func getSNativeInfo(permString string) *snativeInfo {
+// newSNativeInfo looks up the permission flag for permString and builds the
+// snativeInfo that both the ABI dispatcher and util/snatives' code
+// generator key off of.
+func newSNativeInfo(permString, signature string, args []abiType, gasCost int64, exec SNativeContract) *snativeInfo {
 	permFlag, err := ptypes.SNativeStringToPermFlag(permString)
 	if err != nil {
 		PanicSanity(err)
 	}
-	si := &snativeInfo{PermFlag: permFlag}
-	var errS string
-	switch permFlag {
-	case ptypes.HasBase:
-		si.NArgs, errS, si.Executable = 2, "hasBase() takes two arguments (address, permFlag)", hasBasePerm
-	case ptypes.SetBase:
-		si.NArgs, errS, si.Executable = 3, "setBase() takes three arguments (address, permFlag, permission value)", setBasePerm
-	case ptypes.UnsetBase:
-		si.NArgs, errS, si.Executable = 2, "unsetBase() takes two arguments (address, permFlag)", unsetBasePerm
-	case ptypes.SetGlobal:
-		si.NArgs, errS, si.Executable = 2, "setGlobal() takes two arguments (permFlag, permission value)", setGlobalPerm
-	case ptypes.HasRole:
-		si.NArgs, errS, si.Executable = 2, "hasRole() takes two arguments (address, role)", hasRole
-	case ptypes.AddRole:
-		si.NArgs, errS, si.Executable = 2, "addRole() takes two arguments (address, role)", addRole
-	case ptypes.RmRole:
-		si.NArgs, errS, si.Executable = 2, "rmRole() takes two arguments (address, role)", rmRole
-	default:
-		PanicSanity(Fmt("should never happen. PermFlag: %b", permFlag))
-	}
-	si.ArgsError = fmt.Errorf(errS)
-	return si
+	return &snativeInfo{
+		Name:       permString,
+		Signature:  signature,
+		Args:       args,
+		PermFlag:   permFlag,
+		GasCost:    gasCost,
+		ArgsError:  fmt.Errorf("%s() takes arguments matching %s", permString, signature),
+		Executable: exec,
+	}
+}
+
+// CallSNative is the entry point the VM's CALL/CALLCODE opcodes use to
+// invoke a registered snative contract. input must be at least 4 bytes: a
+// Solidity-style function selector followed by ABI-encoded arguments.
+// caller is the account the CALL/CALLCODE originated from, so the snative
+// can enforce its own permission against it. gas is the caller's remaining
+// gas; si.Executable charges its GasCost against it.
+func CallSNative(appState AppState, caller *Account, input []byte, gas *int64) (output []byte, err error) {
+	if len(input) < 4 {
+		return nil, fmt.Errorf("snative call input too short to contain a function selector")
+	}
+	var sel selector
+	copy(sel[:], input[:4])
+	si, ok := RegisteredSNativeContracts[sel]
+	if !ok {
+		return nil, fmt.Errorf("unknown snative selector 0x%s", sel)
+	}
+	args, err := decodeArgs(input[4:], si.Args)
+	if err != nil {
+		return nil, si.ArgsError
+	}
+	return si.Executable(appState, caller, args, gas)
 }
 
 //-----------------------------------------------------------------------------
@@ -70,133 +132,121 @@ func getSNativeInfo(permString string) *snativeInfo {
 
 // TODO: catch errors, log em, return 0s to the vm (should some errors cause exceptions though?)
 
-func hasBasePerm(appState AppState, args []byte) (output []byte, err error) {
-	addr, permNum := returnTwoArgs(args)
-	vmAcc := appState.GetAccount(addr)
-	if vmAcc == nil {
-		return nil, fmt.Errorf("Unknown account %X", addr)
+func hasBasePerm(appState AppState, caller *Account, args []Word256, gas *int64) (output []byte, err error) {
+	if err := chargeGas(gas, gasCostHasBase); err != nil {
+		return nil, err
 	}
-	permN := ptypes.PermFlag(Uint64FromWord256(permNum)) // already shifted
-	if !ValidPermN(permN) {
-		return nil, ptypes.ErrInvalidPermission(permN)
+	if !HasPermission(appState, caller, ptypes.HasBase) {
+		return nil, ErrInvalidPermission{caller.Address, "HasBase"}
 	}
-	var permInt byte
-	if HasPermission(appState, vmAcc, permN) {
-		permInt = 0x1
-	} else {
-		permInt = 0x0
+	addr := args[0]
+	permN := ptypes.PermFlag(Uint64FromWord256(args[1])) // already shifted
+	hasPerm, err := HasBasePermission(appState, addr, permN)
+	if err != nil {
+		return nil, err
 	}
-	dbg.Printf("snative.hasBasePerm(0x%X, %b) = %v\n", addr.Postfix(20), permN, permInt)
-	return LeftPadWord256([]byte{permInt}).Bytes(), nil
+	logger.Debug("snative.hasBasePerm", "address", addr.Postfix(20), "permission", permN, "result", hasPerm)
+	return encodeBool(hasPerm), nil
 }
 
-func setBasePerm(appState AppState, args []byte) (output []byte, err error) {
-	addr, permNum, perm := returnThreeArgs(args)
-	vmAcc := appState.GetAccount(addr)
-	if vmAcc == nil {
-		return nil, fmt.Errorf("Unknown account %X", addr)
+func setBasePerm(appState AppState, caller *Account, args []Word256, gas *int64) (output []byte, err error) {
+	if err := chargeGas(gas, gasCostSetBase); err != nil {
+		return nil, err
 	}
-	permN := ptypes.PermFlag(Uint64FromWord256(permNum))
-	if !ValidPermN(permN) {
-		return nil, ptypes.ErrInvalidPermission(permN)
+	if !HasPermission(appState, caller, ptypes.SetBase) {
+		return nil, ErrInvalidPermission{caller.Address, "SetBase"}
 	}
-	permV := !perm.IsZero()
-	if err = vmAcc.Permissions.Base.Set(permN, permV); err != nil {
+	addr := args[0]
+	permN := ptypes.PermFlag(Uint64FromWord256(args[1]))
+	permV := !args[2].IsZero()
+	if err = SetBasePermission(appState, addr, permN, permV); err != nil {
 		return nil, err
 	}
-	appState.UpdateAccount(vmAcc)
-	dbg.Printf("snative.setBasePerm(0x%X, %b, %v)\n", addr.Postfix(20), permN, permV)
-	return perm.Bytes(), nil
+	logger.Debug("snative.setBasePerm", "address", addr.Postfix(20), "permission", permN, "value", permV)
+	return encodeBool(permV), nil
 }
 
-func unsetBasePerm(appState AppState, args []byte) (output []byte, err error) {
-	addr, permNum := returnTwoArgs(args)
-	vmAcc := appState.GetAccount(addr)
-	if vmAcc == nil {
-		return nil, fmt.Errorf("Unknown account %X", addr)
+func unsetBasePerm(appState AppState, caller *Account, args []Word256, gas *int64) (output []byte, err error) {
+	if err := chargeGas(gas, gasCostUnsetBase); err != nil {
+		return nil, err
 	}
-	permN := ptypes.PermFlag(Uint64FromWord256(permNum))
-	if !ValidPermN(permN) {
-		return nil, ptypes.ErrInvalidPermission(permN)
+	if !HasPermission(appState, caller, ptypes.UnsetBase) {
+		return nil, ErrInvalidPermission{caller.Address, "UnsetBase"}
 	}
-	if err = vmAcc.Permissions.Base.Unset(permN); err != nil {
+	addr := args[0]
+	permN := ptypes.PermFlag(Uint64FromWord256(args[1]))
+	if err = UnsetBasePermission(appState, addr, permN); err != nil {
 		return nil, err
 	}
-	appState.UpdateAccount(vmAcc)
-	dbg.Printf("snative.unsetBasePerm(0x%X, %b)\n", addr.Postfix(20), permN)
-	return permNum.Bytes(), nil
+	logger.Debug("snative.unsetBasePerm", "address", addr.Postfix(20), "permission", permN)
+	return encodeBool(true), nil
 }
 
-func setGlobalPerm(appState AppState, args []byte) (output []byte, err error) {
-	permNum, perm := returnTwoArgs(args)
-	vmAcc := appState.GetAccount(ptypes.GlobalPermissionsAddress256)
-	if vmAcc == nil {
-		PanicSanity("cant find the global permissions account")
+func setGlobalPerm(appState AppState, caller *Account, args []Word256, gas *int64) (output []byte, err error) {
+	if err := chargeGas(gas, gasCostSetGlobal); err != nil {
+		return nil, err
 	}
-	permN := ptypes.PermFlag(Uint64FromWord256(permNum))
-	if !ValidPermN(permN) {
-		return nil, ptypes.ErrInvalidPermission(permN)
+	if !HasPermission(appState, caller, ptypes.SetGlobal) {
+		return nil, ErrInvalidPermission{caller.Address, "SetGlobal"}
 	}
-	permV := !perm.IsZero()
-	if err = vmAcc.Permissions.Base.Set(permN, permV); err != nil {
+	permN := ptypes.PermFlag(Uint64FromWord256(args[0]))
+	permV := !args[1].IsZero()
+	if err = SetGlobalPermission(appState, permN, permV); err != nil {
 		return nil, err
 	}
-	appState.UpdateAccount(vmAcc)
-	dbg.Printf("snative.setGlobalPerm(%b, %v)\n", permN, permV)
-	return perm.Bytes(), nil
+	logger.Debug("snative.setGlobalPerm", "permission", permN, "value", permV)
+	return encodeBool(permV), nil
 }
 
-func hasRole(appState AppState, args []byte) (output []byte, err error) {
-	addr, role := returnTwoArgs(args)
-	vmAcc := appState.GetAccount(addr)
-	if vmAcc == nil {
-		return nil, fmt.Errorf("Unknown account %X", addr)
-	}
-	roleS := string(role.Bytes())
-	var permInt byte
-	if vmAcc.Permissions.HasRole(roleS) {
-		permInt = 0x1
-	} else {
-		permInt = 0x0
-	}
-	dbg.Printf("snative.hasRole(0x%X, %s) = %v\n", addr.Postfix(20), roleS, permInt > 0)
-	return LeftPadWord256([]byte{permInt}).Bytes(), nil
+func hasRole(appState AppState, caller *Account, args []Word256, gas *int64) (output []byte, err error) {
+	if err := chargeGas(gas, gasCostHasRole); err != nil {
+		return nil, err
+	}
+	if !HasPermission(appState, caller, ptypes.HasRole) {
+		return nil, ErrInvalidPermission{caller.Address, "HasRole"}
+	}
+	addr := args[0]
+	roleS := ptypes.TrimmedString(args[1])
+	has, err := HasRolePermission(appState, addr, roleS)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("snative.hasRole", "address", addr.Postfix(20), "role", roleS, "result", has)
+	return encodeBool(has), nil
 }
 
-func addRole(appState AppState, args []byte) (output []byte, err error) {
-	addr, role := returnTwoArgs(args)
-	vmAcc := appState.GetAccount(addr)
-	if vmAcc == nil {
-		return nil, fmt.Errorf("Unknown account %X", addr)
-	}
-	roleS := string(role.Bytes())
-	var permInt byte
-	if vmAcc.Permissions.AddRole(roleS) {
-		permInt = 0x1
-	} else {
-		permInt = 0x0
-	}
-	appState.UpdateAccount(vmAcc)
-	dbg.Printf("snative.addRole(0x%X, %s) = %v\n", addr.Postfix(20), roleS, permInt > 0)
-	return LeftPadWord256([]byte{permInt}).Bytes(), nil
+func addRole(appState AppState, caller *Account, args []Word256, gas *int64) (output []byte, err error) {
+	if err := chargeGas(gas, gasCostAddRole); err != nil {
+		return nil, err
+	}
+	if !HasPermission(appState, caller, ptypes.AddRole) {
+		return nil, ErrInvalidPermission{caller.Address, "AddRole"}
+	}
+	addr := args[0]
+	roleS := ptypes.TrimmedString(args[1])
+	added, err := AddRolePermission(appState, caller, addr, roleS)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("snative.addRole", "address", addr.Postfix(20), "role", roleS, "result", added)
+	return encodeBool(added), nil
 }
 
-func rmRole(appState AppState, args []byte) (output []byte, err error) {
-	addr, role := returnTwoArgs(args)
-	vmAcc := appState.GetAccount(addr)
-	if vmAcc == nil {
-		return nil, fmt.Errorf("Unknown account %X", addr)
-	}
-	roleS := string(role.Bytes())
-	var permInt byte
-	if vmAcc.Permissions.RmRole(roleS) {
-		permInt = 0x1
-	} else {
-		permInt = 0x0
-	}
-	appState.UpdateAccount(vmAcc)
-	dbg.Printf("snative.rmRole(0x%X, %s) = %v\n", addr.Postfix(20), roleS, permInt > 0)
-	return LeftPadWord256([]byte{permInt}).Bytes(), nil
+func rmRole(appState AppState, caller *Account, args []Word256, gas *int64) (output []byte, err error) {
+	if err := chargeGas(gas, gasCostRmRole); err != nil {
+		return nil, err
+	}
+	if !HasPermission(appState, caller, ptypes.RmRole) {
+		return nil, ErrInvalidPermission{caller.Address, "RmRole"}
+	}
+	addr := args[0]
+	roleS := ptypes.TrimmedString(args[1])
+	removed, err := RmRolePermission(appState, caller, addr, roleS)
+	if err != nil {
+		return nil, err
+	}
+	logger.Debug("snative.rmRole", "address", addr.Postfix(20), "role", roleS, "result", removed)
+	return encodeBool(removed), nil
 }
 
 //------------------------------------------------------------------------------------------------
@@ -220,18 +270,3 @@ func ValidPermN(n ptypes.PermFlag) bool {
 	}
 	return true
 }
-
-// CONTRACT: length has already been checked
-func returnTwoArgs(args []byte) (a Word256, b Word256) {
-	copy(a[:], args[:32])
-	copy(b[:], args[32:64])
-	return
-}
-
-// CONTRACT: length has already been checked
-func returnThreeArgs(args []byte) (a Word256, b Word256, c Word256) {
-	copy(a[:], args[:32])
-	copy(b[:], args[32:64])
-	copy(c[:], args[64:96])
-	return
-}